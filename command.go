@@ -0,0 +1,175 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/schillermann/vimgo-playground/internal/lineedit"
+	"github.com/schillermann/vimgo-playground/internal/screen"
+)
+
+// EditorMode selects how key events from keyChannel are interpreted.
+type EditorMode int
+
+const (
+	ModeNormal EditorMode = iota
+	ModeInsert
+	ModeVisual
+	ModeCommand
+)
+
+const historyMaxEntries = 1000
+const historyFileName = ".vimgo_history"
+
+var editorMode EditorMode
+var commandEditor *lineedit.Editor
+var commandHistory *lineedit.History
+var currentFilename string
+
+// historyFilePath returns the path to the persisted command history,
+// falling back to a relative file if the home directory can't be found.
+func historyFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return historyFileName
+	}
+	return filepath.Join(home, historyFileName)
+}
+
+// keyEventToLineEditEvent translates a terminal KeyEvent into the small
+// key vocabulary the lineedit package understands.
+func keyEventToLineEditEvent(ev KeyEvent) lineedit.Event {
+	if ev.Ctrl {
+		switch ev.Rune {
+		case 'a':
+			return lineedit.Event{Key: lineedit.KeyCtrlA}
+		case 'e':
+			return lineedit.Event{Key: lineedit.KeyCtrlE}
+		case 'u':
+			return lineedit.Event{Key: lineedit.KeyCtrlU}
+		case 'w':
+			return lineedit.Event{Key: lineedit.KeyCtrlW}
+		case 'c':
+			return lineedit.Event{Key: lineedit.KeyCtrlC}
+		case 'd':
+			return lineedit.Event{Key: lineedit.KeyCtrlD}
+		}
+	}
+
+	switch ev.KeyCode {
+	case KeyEnter:
+		return lineedit.Event{Key: lineedit.KeyEnter}
+	case KeyEsc:
+		return lineedit.Event{Key: lineedit.KeyEsc}
+	case KeyBackspace:
+		return lineedit.Event{Key: lineedit.KeyBackspace}
+	case KeyDelete:
+		return lineedit.Event{Key: lineedit.KeyDelete}
+	case KeyArrowLeft:
+		return lineedit.Event{Key: lineedit.KeyArrowLeft}
+	case KeyArrowRight:
+		return lineedit.Event{Key: lineedit.KeyArrowRight}
+	case KeyArrowUp:
+		return lineedit.Event{Key: lineedit.KeyArrowUp}
+	case KeyArrowDown:
+		return lineedit.Event{Key: lineedit.KeyArrowDown}
+	case KeyHome:
+		return lineedit.Event{Key: lineedit.KeyHome}
+	case KeyEnd:
+		return lineedit.Event{Key: lineedit.KeyEnd}
+	case KeyTab:
+		return lineedit.Event{Key: lineedit.KeyTab}
+	default:
+		return lineedit.Event{Key: lineedit.KeyRune, Rune: ev.Rune}
+	}
+}
+
+// screenKeyEventToKeyEvent translates an InputScreen backend's key event
+// (currently only the tcell backend) into vimgo's own KeyEvent, the
+// mirror of keyEventToLineEditEvent above.
+func screenKeyEventToKeyEvent(ev screen.KeyEvent) KeyEvent {
+	out := KeyEvent{Rune: ev.Rune, Ctrl: ev.Ctrl}
+	switch ev.KeyCode {
+	case screen.KeyArrowUp:
+		out.KeyCode = KeyArrowUp
+	case screen.KeyArrowDown:
+		out.KeyCode = KeyArrowDown
+	case screen.KeyArrowLeft:
+		out.KeyCode = KeyArrowLeft
+	case screen.KeyArrowRight:
+		out.KeyCode = KeyArrowRight
+	case screen.KeyHome:
+		out.KeyCode = KeyHome
+	case screen.KeyEnd:
+		out.KeyCode = KeyEnd
+	case screen.KeyPageUp:
+		out.KeyCode = KeyPageUp
+	case screen.KeyPageDown:
+		out.KeyCode = KeyPageDown
+	case screen.KeyDelete:
+		out.KeyCode = KeyDelete
+	case screen.KeyBackspace:
+		out.KeyCode = KeyBackspace
+	case screen.KeyTab:
+		out.KeyCode = KeyTab
+	case screen.KeyEnter:
+		out.KeyCode = KeyEnter
+	case screen.KeyEsc:
+		out.KeyCode = KeyEsc
+	case screen.KeyRune:
+		out.KeyCode = KeyRune
+	default:
+		out.KeyCode = KeyUnknown
+	}
+	return out
+}
+
+// executeExCommand runs a completed `:` command line. It reports whether
+// the editor should quit.
+func executeExCommand(line string) (quit bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+
+	cmd, arg := fields[0], strings.Join(fields[1:], " ")
+
+	switch cmd {
+	case "w":
+		exWrite(arg)
+	case "wq":
+		exWrite(arg)
+		return true
+	case "q", "q!":
+		return true
+	case "e":
+		if arg != "" {
+			// Best-effort open: there is no status line yet to surface
+			// errors on, matching editorOpen's existing behavior of
+			// starting with an empty buffer if the file can't be read.
+			_ = editorOpen(arg)
+			currentFilename = arg
+			cursorIndexX, cursorIndexY = 0, 0
+		}
+	}
+	return false
+}
+
+// exWrite implements `:w [file]`, saving to filename if given or the
+// filename the buffer was opened with otherwise.
+func exWrite(filename string) {
+	if filename != "" {
+		currentFilename = filename
+	}
+	if currentFilename == "" {
+		return
+	}
+	// Best-effort, same reasoning as exec above: no status line yet.
+	_ = editorSave(currentFilename)
+}
+
+// editorSave writes the buffer's contents to filename verbatim.
+func editorSave(filename string) error {
+	return os.WriteFile(filename, currentBuffer.Bytes(), 0644)
+}
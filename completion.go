@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// exCommandNames lists the ex-commands completable after `:`. Keep this in
+// sync with the switch in executeExCommand.
+var exCommandNames = []string{"w", "wq", "q", "q!", "e"}
+
+// exCompleter implements lineedit.Completer for vimgo's command mode: it
+// completes command names right after `:`, and filenames for `:e`/`:w`/`:wq`.
+type exCompleter struct{}
+
+func (exCompleter) Complete(line string, pos int) (head string, completions []string, tail string) {
+	runes := []rune(line)
+	if pos > len(runes) {
+		pos = len(runes)
+	}
+	prefix := string(runes[:pos])
+	tail = string(runes[pos:])
+
+	spaceIndex := strings.LastIndexByte(prefix, ' ')
+	if spaceIndex == -1 {
+		return "", completeCommandName(prefix), tail
+	}
+
+	cmd := strings.TrimSpace(prefix[:spaceIndex])
+	if cmd != "e" && cmd != "w" && cmd != "wq" {
+		return prefix, nil, tail
+	}
+
+	head = prefix[:spaceIndex+1]
+	completions = completeFilename(prefix[spaceIndex+1:])
+	return head, completions, tail
+}
+
+func completeCommandName(token string) []string {
+	var completions []string
+	for _, name := range exCommandNames {
+		if strings.HasPrefix(name, token) {
+			completions = append(completions, name)
+		}
+	}
+	return completions
+}
+
+func completeFilename(token string) []string {
+	dir, base := filepath.Split(token)
+	searchDir := dir
+	if searchDir == "" {
+		searchDir = "."
+	}
+
+	entries, err := os.ReadDir(searchDir)
+	if err != nil {
+		return nil
+	}
+
+	var completions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		completions = append(completions, dir+name)
+	}
+	return completions
+}
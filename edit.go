@@ -0,0 +1,115 @@
+package main
+
+import "github.com/schillermann/vimgo-playground/internal/buffer"
+
+// visualAnchorX and visualAnchorY hold the cursor position where Visual
+// mode was entered. Visual mode is movement-only for now: it tracks the
+// anchor but doesn't yet act on the selection or render it.
+var visualAnchorX, visualAnchorY int
+
+// handleNormalKey processes one key event in Normal mode. It reports
+// whether the editor should quit.
+func handleNormalKey(ev KeyEvent) (quit bool) {
+	if ev.Ctrl {
+		switch ev.Rune {
+		case 'r':
+			currentBuffer.Redo()
+			clampCursor()
+			return false
+		}
+	}
+
+	switch ev.Rune {
+	case ':':
+		editorMode = ModeCommand
+		commandEditor.Begin(":")
+		return false
+	case 'i':
+		editorMode = ModeInsert
+		return false
+	case 'v':
+		editorMode = ModeVisual
+		visualAnchorX, visualAnchorY = cursorIndexX, cursorIndexY
+		return false
+	case 'u':
+		currentBuffer.Undo()
+		clampCursor()
+		return false
+	}
+
+	editorMoveCursor(ev)
+	return false
+}
+
+// handleVisualKey processes one key event in Visual mode. Only cursor
+// movement and leaving the mode are implemented; there is no selection
+// operation yet.
+func handleVisualKey(ev KeyEvent) {
+	if ev.KeyCode == KeyEsc {
+		editorMode = ModeNormal
+		return
+	}
+	editorMoveCursor(ev)
+}
+
+// handleInsertKey processes one key event in Insert mode, feeding
+// printable runes, Enter, Backspace and Delete into currentBuffer at the
+// cursor's byte offset.
+func handleInsertKey(ev KeyEvent) {
+	switch ev.KeyCode {
+	case KeyEsc:
+		editorMode = ModeNormal
+		return
+	case KeyEnter:
+		currentBuffer.Insert(cursorOffset(), "\n")
+		cursorIndexY++
+		cursorIndexX = 0
+		clampCursor()
+		return
+	case KeyBackspace:
+		offset := cursorOffset()
+		_, size := buffer.RuneBefore(currentBuffer, offset)
+		if size == 0 {
+			return
+		}
+		joiningLines := cursorIndexX == 0 && cursorIndexY > 0
+		var prevLineLen int
+		if joiningLines {
+			prevLineLen = len([]rune(string(currentBuffer.Line(cursorIndexY - 1))))
+		}
+		currentBuffer.Delete(offset-size, size)
+		if cursorIndexX > 0 {
+			cursorIndexX--
+		} else if joiningLines {
+			cursorIndexY--
+			cursorIndexX = prevLineLen
+		}
+		clampCursor()
+		return
+	case KeyDelete:
+		_, size := buffer.RuneAt(currentBuffer, cursorOffset())
+		if size == 0 {
+			return
+		}
+		currentBuffer.Delete(cursorOffset(), size)
+		clampCursor()
+		return
+	case KeyTab:
+		currentBuffer.Insert(cursorOffset(), "\t")
+		cursorIndexX++
+		clampCursor()
+		return
+	}
+
+	switch ev.KeyCode {
+	case KeyArrowLeft, KeyArrowRight, KeyArrowUp, KeyArrowDown, KeyHome, KeyEnd:
+		editorMoveCursor(ev)
+		return
+	}
+
+	if ev.KeyCode == KeyRune && !ev.Ctrl {
+		currentBuffer.Insert(cursorOffset(), string(ev.Rune))
+		cursorIndexX++
+		clampCursor()
+	}
+}
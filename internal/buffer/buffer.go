@@ -0,0 +1,363 @@
+/*
+Package buffer implements an undoable piece-table text buffer, the
+editable document behind vimgo's modal editor. The document is never
+rewritten in place: the original file bytes and everything typed since
+are kept as two immutable byte slices ("original" and "add"), and the
+document itself is a slice of pieces, each a span into one of the two.
+Insert and Delete only ever split or trim pieces, so undo/redo is just
+pushing and popping piece-list snapshots rather than reversing byte
+edits.
+*/
+package buffer
+
+import "unicode/utf8"
+
+type pieceSource int
+
+const (
+	sourceOriginal pieceSource = iota
+	sourceAdd
+)
+
+// piece is a span of length bytes starting at start within either the
+// original file bytes or the append-only add buffer.
+type piece struct {
+	source pieceSource
+	start  int
+	length int
+}
+
+// Buffer is an undoable piece-table text buffer.
+type Buffer struct {
+	original []byte
+	add      []byte
+	pieces   []piece
+
+	undoStack [][]piece
+	redoStack [][]piece
+
+	// lineStarts and content cache the byte offset of each line's start and
+	// the fully materialized document they were computed from. Both are
+	// invalidated (set to nil) by any edit and rebuilt lazily on the next
+	// line access; Iterate below avoids this materialization entirely for
+	// callers that only need a byte range.
+	lineStarts []int
+	content    []byte
+}
+
+// New creates a Buffer seeded with original's bytes as its initial content.
+// original may be nil for an empty buffer.
+func New(original []byte) *Buffer {
+	b := &Buffer{original: original}
+	if len(original) > 0 {
+		b.pieces = []piece{{source: sourceOriginal, start: 0, length: len(original)}}
+	}
+	return b
+}
+
+// Len returns the document length in bytes.
+func (b *Buffer) Len() int {
+	n := 0
+	for _, p := range b.pieces {
+		n += p.length
+	}
+	return n
+}
+
+// Bytes materializes the whole document. Used for writing the buffer out
+// to disk; line access goes through LineCount/Line/Iterate instead.
+func (b *Buffer) Bytes() []byte {
+	out := make([]byte, 0, b.Len())
+	for _, p := range b.pieces {
+		out = append(out, b.pieceBytes(p)...)
+	}
+	return out
+}
+
+func (b *Buffer) pieceBytes(p piece) []byte {
+	if p.source == sourceOriginal {
+		return b.original[p.start : p.start+p.length]
+	}
+	return b.add[p.start : p.start+p.length]
+}
+
+// Insert inserts text at the given byte offset.
+func (b *Buffer) Insert(offset int, text string) {
+	if text == "" {
+		return
+	}
+	b.pushUndo()
+
+	addStart := len(b.add)
+	b.add = append(b.add, text...)
+	newPiece := piece{source: sourceAdd, start: addStart, length: len(text)}
+
+	if len(b.pieces) == 0 {
+		b.pieces = []piece{newPiece}
+	} else {
+		b.pieces = b.spliceIn(offset, newPiece)
+	}
+	b.invalidateLines()
+}
+
+// spliceIn inserts newPiece at offset by splitting the piece it lands in.
+func (b *Buffer) spliceIn(offset int, newPiece piece) []piece {
+	idx, inner := b.pieceAt(offset)
+	result := make([]piece, 0, len(b.pieces)+2)
+	result = append(result, b.pieces[:idx]...)
+
+	p := b.pieces[idx]
+	if inner > 0 {
+		result = append(result, piece{source: p.source, start: p.start, length: inner})
+	}
+	result = append(result, newPiece)
+	if inner < p.length {
+		result = append(result, piece{source: p.source, start: p.start + inner, length: p.length - inner})
+	}
+
+	return append(result, b.pieces[idx+1:]...)
+}
+
+// pieceAt returns the index of the piece containing offset and the offset
+// within that piece. An offset at the very end of the document returns the
+// last piece's index and its full length.
+func (b *Buffer) pieceAt(offset int) (index, inner int) {
+	cum := 0
+	for i, p := range b.pieces {
+		if offset <= cum+p.length {
+			return i, offset - cum
+		}
+		cum += p.length
+	}
+	return len(b.pieces), 0
+}
+
+// Delete removes length bytes starting at offset, clamped to the document.
+func (b *Buffer) Delete(offset, length int) {
+	if length <= 0 {
+		return
+	}
+	total := b.Len()
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total {
+		return
+	}
+	if offset+length > total {
+		length = total - offset
+	}
+
+	b.pushUndo()
+	b.pieces = b.spliceOut(offset, length)
+	b.invalidateLines()
+}
+
+// spliceOut returns the pieces left after removing [offset, offset+length).
+func (b *Buffer) spliceOut(offset, length int) []piece {
+	end := offset + length
+	result := make([]piece, 0, len(b.pieces)+1)
+
+	cum := 0
+	for _, p := range b.pieces {
+		pStart, pEnd := cum, cum+p.length
+		cum = pEnd
+
+		if pStart < offset {
+			keepLen := offset - pStart
+			if keepLen > p.length {
+				keepLen = p.length
+			}
+			if keepLen > 0 {
+				result = append(result, piece{source: p.source, start: p.start, length: keepLen})
+			}
+		}
+		if pEnd > end {
+			skipLen := end - pStart
+			if skipLen < 0 {
+				skipLen = 0
+			}
+			if skipLen < p.length {
+				result = append(result, piece{source: p.source, start: p.start + skipLen, length: p.length - skipLen})
+			}
+		}
+	}
+
+	return result
+}
+
+// pushUndo snapshots the current piece list and clears the redo stack,
+// matching the usual editor convention that a fresh edit abandons any
+// previously undone redo history.
+func (b *Buffer) pushUndo() {
+	b.undoStack = append(b.undoStack, append([]piece(nil), b.pieces...))
+	b.redoStack = nil
+}
+
+// Undo restores the piece list from before the last edit. It reports
+// whether there was anything to undo.
+func (b *Buffer) Undo() bool {
+	if len(b.undoStack) == 0 {
+		return false
+	}
+	last := len(b.undoStack) - 1
+	snapshot := b.undoStack[last]
+	b.undoStack = b.undoStack[:last]
+
+	b.redoStack = append(b.redoStack, append([]piece(nil), b.pieces...))
+	b.pieces = snapshot
+	b.invalidateLines()
+	return true
+}
+
+// Redo reapplies the most recently undone edit. It reports whether there
+// was anything to redo.
+func (b *Buffer) Redo() bool {
+	if len(b.redoStack) == 0 {
+		return false
+	}
+	last := len(b.redoStack) - 1
+	snapshot := b.redoStack[last]
+	b.redoStack = b.redoStack[:last]
+
+	b.undoStack = append(b.undoStack, append([]piece(nil), b.pieces...))
+	b.pieces = snapshot
+	b.invalidateLines()
+	return true
+}
+
+func (b *Buffer) invalidateLines() {
+	b.lineStarts = nil
+	b.content = nil
+}
+
+// ensureLines materializes the document and indexes line-start offsets, if
+// the cache was invalidated by an edit since the last call. A file ending
+// in "\n" does not get an extra trailing empty line, matching how
+// editorOpen used to read files line by line with bufio.Scanner.
+func (b *Buffer) ensureLines() {
+	if b.lineStarts != nil {
+		return
+	}
+	b.content = b.Bytes()
+	if len(b.content) == 0 {
+		b.lineStarts = []int{}
+		return
+	}
+
+	starts := []int{0}
+	for i, c := range b.content {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	if starts[len(starts)-1] == len(b.content) {
+		starts = starts[:len(starts)-1]
+	}
+	b.lineStarts = starts
+}
+
+// LineCount returns the number of lines in the document. An empty
+// document has zero lines.
+func (b *Buffer) LineCount() int {
+	b.ensureLines()
+	return len(b.lineStarts)
+}
+
+// Line returns line i's bytes, excluding its trailing newline (and a
+// preceding "\r", to match editorOpen's old bufio.Scanner-based reading of
+// CRLF files). It returns nil if i is out of range.
+func (b *Buffer) Line(i int) []byte {
+	b.ensureLines()
+	if i < 0 || i >= len(b.lineStarts) {
+		return nil
+	}
+
+	start := b.lineStarts[i]
+	end := start
+	for end < len(b.content) && b.content[end] != '\n' {
+		end++
+	}
+	if end > start && b.content[end-1] == '\r' {
+		end--
+	}
+	return b.content[start:end]
+}
+
+// LineStart returns line i's byte offset into the document, the form
+// Buffer.Insert/Delete operate on. It returns len(Bytes()) if i is out of
+// range, so callers computing an offset from an out-of-range line (e.g.
+// one past the last) land at the end of the document.
+func (b *Buffer) LineStart(i int) int {
+	b.ensureLines()
+	if i < 0 || i >= len(b.lineStarts) {
+		return len(b.content)
+	}
+	return b.lineStarts[i]
+}
+
+// Iterate returns the document bytes in [from, to), walking only the
+// pieces that overlap the range instead of materializing the whole
+// document, so a viewport can be rendered without copying a large file.
+func (b *Buffer) Iterate(from, to int) []byte {
+	if from < 0 {
+		from = 0
+	}
+	if total := b.Len(); to > total {
+		to = total
+	}
+	if from >= to {
+		return nil
+	}
+
+	out := make([]byte, 0, to-from)
+	cum := 0
+	for _, p := range b.pieces {
+		pStart, pEnd := cum, cum+p.length
+		cum = pEnd
+
+		if pEnd <= from {
+			continue
+		}
+		if pStart >= to {
+			break
+		}
+
+		segStart, segEnd := from, to
+		if pStart > segStart {
+			segStart = pStart
+		}
+		if pEnd < segEnd {
+			segEnd = pEnd
+		}
+		out = append(out, b.pieceBytes(p)[segStart-pStart:segEnd-pStart]...)
+	}
+	return out
+}
+
+// RuneBefore returns the rune ending at offset and its byte width, for
+// callers that need to step backward by one rune (e.g. Backspace).
+func RuneBefore(b *Buffer, offset int) (r rune, size int) {
+	if offset <= 0 {
+		return 0, 0
+	}
+	lookback := offset
+	if lookback > utf8.UTFMax {
+		lookback = utf8.UTFMax
+	}
+	return utf8.DecodeLastRune(b.Iterate(offset-lookback, offset))
+}
+
+// RuneAt returns the rune starting at offset and its byte width, for
+// callers that need to step forward by one rune (e.g. Delete).
+func RuneAt(b *Buffer, offset int) (r rune, size int) {
+	total := b.Len()
+	if offset >= total {
+		return 0, 0
+	}
+	lookahead := offset + utf8.UTFMax
+	if lookahead > total {
+		lookahead = total
+	}
+	return utf8.DecodeRune(b.Iterate(offset, lookahead))
+}
@@ -0,0 +1,180 @@
+package buffer
+
+import "testing"
+
+func TestNewBytes(t *testing.T) {
+	b := New([]byte("hello"))
+	if got := string(b.Bytes()); got != "hello" {
+		t.Fatalf("Bytes() = %q, want %q", got, "hello")
+	}
+	if b.Len() != 5 {
+		t.Fatalf("Len() = %d, want 5", b.Len())
+	}
+}
+
+func TestInsert(t *testing.T) {
+	b := New([]byte("helloworld"))
+	b.Insert(5, " ")
+	if got := string(b.Bytes()); got != "hello world" {
+		t.Fatalf("Bytes() = %q, want %q", got, "hello world")
+	}
+}
+
+func TestInsertAtPieceBoundary(t *testing.T) {
+	b := New([]byte("abc"))
+	b.Insert(3, "def") // new piece appended after the original piece
+	b.Insert(3, "-")   // lands exactly on the boundary between the two pieces
+	if got := string(b.Bytes()); got != "abc-def" {
+		t.Fatalf("Bytes() = %q, want %q", got, "abc-def")
+	}
+}
+
+func TestInsertIntoEmptyBuffer(t *testing.T) {
+	b := New(nil)
+	b.Insert(0, "x")
+	if got := string(b.Bytes()); got != "x" {
+		t.Fatalf("Bytes() = %q, want %q", got, "x")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	b := New([]byte("hello world"))
+	b.Delete(5, 6)
+	if got := string(b.Bytes()); got != "hello" {
+		t.Fatalf("Bytes() = %q, want %q", got, "hello")
+	}
+}
+
+func TestDeleteSpanningMultiplePieces(t *testing.T) {
+	b := New([]byte("abcdef"))
+	b.Insert(3, "123") // pieces: "abc" "123" "def"
+	b.Insert(0, "X")   // pieces: "X" "abc" "123" "def"
+	b.Delete(2, 5)     // removes "bc123" across three pieces, leaving "Xadef"
+	if got := string(b.Bytes()); got != "Xadef" {
+		t.Fatalf("Bytes() = %q, want %q", got, "Xadef")
+	}
+}
+
+func TestDeleteClampsToDocument(t *testing.T) {
+	b := New([]byte("abc"))
+	b.Delete(1, 100)
+	if got := string(b.Bytes()); got != "a" {
+		t.Fatalf("Bytes() = %q, want %q", got, "a")
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	b := New([]byte("abc"))
+	b.Insert(3, "def")
+	b.Delete(0, 1)
+	if got := string(b.Bytes()); got != "bcdef" {
+		t.Fatalf("Bytes() after edits = %q, want %q", got, "bcdef")
+	}
+
+	if !b.Undo() {
+		t.Fatal("Undo() = false, want true")
+	}
+	if got := string(b.Bytes()); got != "abcdef" {
+		t.Fatalf("Bytes() after Undo = %q, want %q", got, "abcdef")
+	}
+
+	if !b.Undo() {
+		t.Fatal("Undo() = false, want true")
+	}
+	if got := string(b.Bytes()); got != "abc" {
+		t.Fatalf("Bytes() after second Undo = %q, want %q", got, "abc")
+	}
+	if b.Undo() {
+		t.Fatal("Undo() = true on empty undo stack, want false")
+	}
+
+	if !b.Redo() {
+		t.Fatal("Redo() = false, want true")
+	}
+	if got := string(b.Bytes()); got != "abcdef" {
+		t.Fatalf("Bytes() after Redo = %q, want %q", got, "abcdef")
+	}
+}
+
+func TestEditAfterUndoDiscardsRedo(t *testing.T) {
+	b := New([]byte("abc"))
+	b.Insert(3, "1")
+	b.Undo()
+	b.Insert(3, "2")
+	if b.Redo() {
+		t.Fatal("Redo() = true after a fresh edit, want false")
+	}
+	if got := string(b.Bytes()); got != "abc2" {
+		t.Fatalf("Bytes() = %q, want %q", got, "abc2")
+	}
+}
+
+func TestLineCountAndLine(t *testing.T) {
+	b := New([]byte("one\ntwo\nthree"))
+	if b.LineCount() != 3 {
+		t.Fatalf("LineCount() = %d, want 3", b.LineCount())
+	}
+	for i, want := range []string{"one", "two", "three"} {
+		if got := string(b.Line(i)); got != want {
+			t.Fatalf("Line(%d) = %q, want %q", i, got, want)
+		}
+	}
+	if b.Line(3) != nil {
+		t.Fatalf("Line(3) = %q, want nil", b.Line(3))
+	}
+}
+
+func TestLineStripsTrailingNewlineOnLastLine(t *testing.T) {
+	b := New([]byte("abc\ndef\n"))
+	if got, want := b.LineCount(), 2; got != want {
+		t.Fatalf("LineCount() = %d, want %d", got, want)
+	}
+	if got := string(b.Line(1)); got != "def" {
+		t.Fatalf("Line(1) = %q, want %q", got, "def")
+	}
+}
+
+func TestLineStripsCRLF(t *testing.T) {
+	b := New([]byte("abc\r\ndef\r\nghi\r\n"))
+	for i, want := range []string{"abc", "def", "ghi"} {
+		if got := string(b.Line(i)); got != want {
+			t.Fatalf("Line(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestLineStartMatchesRealOffsetsForCRLF(t *testing.T) {
+	b := New([]byte("abc\r\ndef\r\nghi\r\n"))
+	want := []int{0, 5, 10}
+	for i, w := range want {
+		if got := b.LineStart(i); got != w {
+			t.Fatalf("LineStart(%d) = %d, want %d", i, got, w)
+		}
+	}
+}
+
+func TestIterate(t *testing.T) {
+	b := New([]byte("abcdef"))
+	b.Insert(3, "123") // pieces: "abc" "123" "def"
+	if got := string(b.Iterate(2, 7)); got != "c123d" {
+		t.Fatalf("Iterate(2, 7) = %q, want %q", got, "c123d")
+	}
+}
+
+func TestRuneBeforeAndRuneAt(t *testing.T) {
+	b := New([]byte("aéb")) // 'é' is 2 bytes, so offsets straddle a multi-byte rune
+	r, size := RuneBefore(b, 3)
+	if r != 'é' || size != 2 {
+		t.Fatalf("RuneBefore(3) = %q, %d, want 'é', 2", r, size)
+	}
+	r, size = RuneAt(b, 1)
+	if r != 'é' || size != 2 {
+		t.Fatalf("RuneAt(1) = %q, %d, want 'é', 2", r, size)
+	}
+	if r, size := RuneBefore(b, 0); r != 0 || size != 0 {
+		t.Fatalf("RuneBefore(0) = %q, %d, want 0, 0", r, size)
+	}
+	if r, size := RuneAt(b, b.Len()); r != 0 || size != 0 {
+		t.Fatalf("RuneAt(Len()) = %q, %d, want 0, 0", r, size)
+	}
+}
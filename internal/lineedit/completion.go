@@ -0,0 +1,96 @@
+package lineedit
+
+import "strings"
+
+// Completer provides Tab-completion candidates for an Editor. line and pos
+// are the buffer and cursor position at the time Tab was pressed. head and
+// tail are the portions of line before and after the token being
+// completed; completions are full replacement strings for that token, so
+// the resulting line after accepting completions[i] is head+completions[i]+tail.
+type Completer interface {
+	Complete(line string, pos int) (head string, completions []string, tail string)
+}
+
+// handleTab runs the installed Completer and applies its result: a single
+// candidate is inserted outright, multiple candidates insert their longest
+// common prefix and arm the bell/candidate list for a repeated Tab.
+func (e *Editor) handleTab() {
+	if e.completer == nil {
+		return
+	}
+
+	head, completions, tail := e.completer.Complete(string(e.buf), e.pos)
+	if len(completions) == 0 {
+		e.tabArmed = false
+		return
+	}
+
+	if len(completions) == 1 {
+		e.setLine(head+completions[0]+tail, len([]rune(head+completions[0])))
+		e.tabArmed = false
+		return
+	}
+
+	lcp := longestCommonPrefix(completions)
+	e.setLine(head+lcp+tail, len([]rune(head+lcp)))
+
+	if e.tabArmed {
+		e.candidates = completions
+	} else {
+		e.bell = true
+	}
+	e.tabArmed = true
+}
+
+func longestCommonPrefix(strs []string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+	prefix := strs[0]
+	for _, s := range strs[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+			if prefix == "" {
+				return ""
+			}
+		}
+	}
+	return prefix
+}
+
+// formatCandidateColumns lays candidates out in as many equal-width
+// columns as fit within width, left to right then top to bottom.
+func formatCandidateColumns(candidates []string, width int) []string {
+	if width <= 0 {
+		width = 80
+	}
+
+	maxLen := 0
+	for _, c := range candidates {
+		if len(c) > maxLen {
+			maxLen = len(c)
+		}
+	}
+
+	columnWidth := maxLen + 2
+	columns := width / columnWidth
+	if columns < 1 {
+		columns = 1
+	}
+
+	var rows []string
+	for i := 0; i < len(candidates); i += columns {
+		end := i + columns
+		if end > len(candidates) {
+			end = len(candidates)
+		}
+
+		var row strings.Builder
+		for _, c := range candidates[i:end] {
+			row.WriteString(c)
+			row.WriteString(strings.Repeat(" ", columnWidth-len(c)))
+		}
+		rows = append(rows, row.String())
+	}
+	return rows
+}
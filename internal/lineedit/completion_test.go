@@ -0,0 +1,91 @@
+package lineedit
+
+import "testing"
+
+func TestLongestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		strs []string
+		want string
+	}{
+		{"empty", nil, ""},
+		{"single", []string{"foo"}, "foo"},
+		{"shared prefix", []string{"foobar", "foobaz", "foo"}, "foo"},
+		{"no overlap", []string{"abc", "xyz"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := longestCommonPrefix(tt.strs); got != tt.want {
+				t.Fatalf("longestCommonPrefix(%v) = %q, want %q", tt.strs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCandidateColumns(t *testing.T) {
+	rows := formatCandidateColumns([]string{"aa", "bb", "cc", "dd"}, 10)
+	if len(rows) != 2 {
+		t.Fatalf("len(rows) = %d, want 2", len(rows))
+	}
+	want := "aa  bb  "
+	if rows[0] != want {
+		t.Fatalf("rows[0] = %q, want %q", rows[0], want)
+	}
+}
+
+// stubCompleter always completes the whole line against a fixed candidate
+// list, with no head/tail, which is enough to exercise handleTab.
+type stubCompleter struct {
+	candidates []string
+}
+
+func (c stubCompleter) Complete(line string, pos int) (head string, completions []string, tail string) {
+	return "", c.candidates, ""
+}
+
+func TestHandleTabSingleCandidateInserts(t *testing.T) {
+	e := New(nil)
+	e.Begin(":")
+	e.SetCompleter(stubCompleter{candidates: []string{"edit"}})
+
+	e.Feed(Event{Key: KeyTab})
+
+	line, pos := e.Line()
+	if line != "edit" || pos != len("edit") {
+		t.Fatalf("Line() = %q, %d, want %q, %d", line, pos, "edit", len("edit"))
+	}
+}
+
+func TestHandleTabMultipleCandidatesInsertsLCPThenShowsList(t *testing.T) {
+	e := New(nil)
+	e.Begin(":")
+	e.SetCompleter(stubCompleter{candidates: []string{"edit", "edita", "editb"}})
+
+	e.Feed(Event{Key: KeyTab})
+	line, pos := e.Line()
+	if line != "edit" || pos != len("edit") {
+		t.Fatalf("after first Tab: Line() = %q, %d, want %q, %d", line, pos, "edit", len("edit"))
+	}
+	if len(e.candidates) != 0 {
+		t.Fatalf("candidates shown after a single Tab, want none yet")
+	}
+
+	e.Feed(Event{Key: KeyTab})
+	if len(e.candidates) != 3 {
+		t.Fatalf("len(candidates) after second Tab = %d, want 3", len(e.candidates))
+	}
+}
+
+func TestHandleTabNoCandidatesIsNoop(t *testing.T) {
+	e := New(nil)
+	e.Begin(":")
+	e.SetCompleter(stubCompleter{candidates: nil})
+	e.insert('x')
+
+	e.Feed(Event{Key: KeyTab})
+
+	line, _ := e.Line()
+	if line != "x" {
+		t.Fatalf("Line() = %q, want %q", line, "x")
+	}
+}
@@ -0,0 +1,75 @@
+package lineedit
+
+import (
+	"bufio"
+	"os"
+)
+
+// History is a bounded ring of previously entered lines, most-recent-last.
+// It can be persisted to and reloaded from a plain-text file, one entry
+// per line.
+type History struct {
+	entries []string
+	max     int
+}
+
+// NewHistory creates an empty History that keeps at most max entries.
+func NewHistory(max int) *History {
+	return &History{max: max}
+}
+
+// LoadHistory reads a History previously written by Save. A missing file
+// is not an error: an empty History bounded by max is returned instead.
+func LoadHistory(path string, max int) (*History, error) {
+	h := NewHistory(max)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return h, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		h.Add(scanner.Text())
+	}
+	return h, scanner.Err()
+}
+
+// Save writes the History to path, one entry per line, oldest first.
+func (h *History) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, entry := range h.entries {
+		if _, err := writer.WriteString(entry + "\n"); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// Add appends a new entry, trimming the oldest one if the ring is full.
+func (h *History) Add(line string) {
+	h.entries = append(h.entries, line)
+	if over := len(h.entries) - h.max; over > 0 {
+		h.entries = h.entries[over:]
+	}
+}
+
+// Len returns the number of stored entries.
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// At returns the i-th most recent entry (0 is the most recently added).
+func (h *History) At(i int) string {
+	return h.entries[len(h.entries)-1-i]
+}
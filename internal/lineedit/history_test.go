@@ -0,0 +1,71 @@
+package lineedit
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryAddAndAt(t *testing.T) {
+	h := NewHistory(3)
+	h.Add("one")
+	h.Add("two")
+	if h.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", h.Len())
+	}
+	if got := h.At(0); got != "two" {
+		t.Fatalf("At(0) = %q, want %q", got, "two")
+	}
+	if got := h.At(1); got != "one" {
+		t.Fatalf("At(1) = %q, want %q", got, "one")
+	}
+}
+
+func TestHistoryDropsOldestWhenFull(t *testing.T) {
+	h := NewHistory(2)
+	h.Add("one")
+	h.Add("two")
+	h.Add("three")
+	if h.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", h.Len())
+	}
+	if got := h.At(0); got != "three" {
+		t.Fatalf("At(0) = %q, want %q", got, "three")
+	}
+	if got := h.At(1); got != "two" {
+		t.Fatalf("At(1) = %q, want %q", got, "two")
+	}
+}
+
+func TestHistorySaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h := NewHistory(10)
+	h.Add("one")
+	h.Add("two")
+	if err := h.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadHistory(path, 10)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if loaded.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", loaded.Len())
+	}
+	if got := loaded.At(0); got != "two" {
+		t.Fatalf("At(0) = %q, want %q", got, "two")
+	}
+}
+
+func TestLoadHistoryMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist")
+
+	h, err := LoadHistory(path, 10)
+	if err != nil {
+		t.Fatalf("LoadHistory() error = %v", err)
+	}
+	if h.Len() != 0 {
+		t.Fatalf("Len() = %d, want 0", h.Len())
+	}
+}
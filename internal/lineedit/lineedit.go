@@ -0,0 +1,277 @@
+/*
+Package lineedit implements a small readline-style single-line editor for
+prompts such as vimgo's ex/command mode. It only knows about a rune buffer,
+a cursor position and (optionally) a History; it has no notion of the
+caller's own text buffer, so it can be reused by any prompt (command mode
+today, a future `/` search prompt tomorrow).
+
+Callers translate their own key events into Events and feed them to an
+Editor one at a time via Feed, then use Render to get the escape sequence
+that redraws the prompt line.
+*/
+package lineedit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrCancelled is returned by Feed when the user aborts editing with
+// Ctrl-C or ESC.
+var ErrCancelled = errors.New("lineedit: input cancelled")
+
+// ANSI escape sequences used to redraw the prompt line. Kept local to this
+// package so it has no dependency on the caller's own terminal handling.
+const (
+	ansiLineClear             = "\033[K"
+	ansiCursorPositionMove    = "\033[%d;%dH"
+	ansiCursorPositionSave    = "\0337"
+	ansiCursorPositionRestore = "\0338"
+)
+
+// Key identifies a keypress relevant to line editing. Callers are
+// responsible for translating their own terminal key events into these.
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyDelete
+	KeyArrowLeft
+	KeyArrowRight
+	KeyArrowUp
+	KeyArrowDown
+	KeyHome
+	KeyEnd
+	KeyCtrlA
+	KeyCtrlE
+	KeyCtrlU
+	KeyCtrlW
+	KeyCtrlC
+	KeyCtrlD
+	KeyTab
+)
+
+// Event is a single key event fed into an Editor.
+type Event struct {
+	Key  Key
+	Rune rune // populated when Key == KeyRune
+}
+
+// Editor is a reusable single-line, readline-style editor with bounded
+// history. It is independent of any particular text buffer: construct one
+// per prompt kind (or share one and call Begin between uses).
+type Editor struct {
+	prompt string
+	buf    []rune
+	pos    int
+
+	history      *History
+	historyIndex int // -1 when not browsing history
+	stash        []rune
+
+	completer  Completer
+	tabArmed   bool     // true if the previous key was also Tab
+	candidates []string // pending candidate list to show above the prompt
+	bell       bool     // pending "\a" to emit on the next Render
+}
+
+// New creates an Editor. history may be nil, in which case up/down arrow
+// navigation is a no-op.
+func New(history *History) *Editor {
+	return &Editor{history: history, historyIndex: -1}
+}
+
+// SetCompleter installs the Completer used for Tab completion. A nil
+// completer (the default) makes Tab a no-op.
+func (e *Editor) SetCompleter(c Completer) {
+	e.completer = c
+}
+
+// Begin resets the editor for a new line with the given prompt.
+func (e *Editor) Begin(prompt string) {
+	e.prompt = prompt
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.historyIndex = -1
+	e.stash = nil
+	e.tabArmed = false
+	e.candidates = nil
+	e.bell = false
+}
+
+// Line returns the buffer's current contents and cursor position.
+func (e *Editor) Line() (line string, pos int) {
+	return string(e.buf), e.pos
+}
+
+// Prompt returns the prompt text set by Begin.
+func (e *Editor) Prompt() string {
+	return e.prompt
+}
+
+// Feed processes one key event. done is true once editing has finished;
+// line then holds the final text. err is io.EOF if Ctrl-D was pressed on
+// an empty line, or ErrCancelled if the user aborted with Ctrl-C/ESC.
+func (e *Editor) Feed(ev Event) (done bool, line string, err error) {
+	if ev.Key != KeyTab {
+		e.tabArmed = false
+		e.candidates = nil
+	}
+
+	switch ev.Key {
+	case KeyTab:
+		e.handleTab()
+	case KeyRune:
+		e.insert(ev.Rune)
+	case KeyBackspace:
+		e.backspace()
+	case KeyDelete:
+		e.deleteForward()
+	case KeyArrowLeft:
+		if e.pos > 0 {
+			e.pos--
+		}
+	case KeyArrowRight:
+		if e.pos < len(e.buf) {
+			e.pos++
+		}
+	case KeyHome, KeyCtrlA:
+		e.pos = 0
+	case KeyEnd, KeyCtrlE:
+		e.pos = len(e.buf)
+	case KeyCtrlU:
+		e.buf = append(e.buf[:0], e.buf[e.pos:]...)
+		e.pos = 0
+	case KeyCtrlW:
+		e.killWordBackward()
+	case KeyArrowUp:
+		e.historyUp()
+	case KeyArrowDown:
+		e.historyDown()
+	case KeyEnter:
+		line = string(e.buf)
+		if line != "" && e.history != nil {
+			e.history.Add(line)
+		}
+		return true, line, nil
+	case KeyEsc, KeyCtrlC:
+		return true, "", ErrCancelled
+	case KeyCtrlD:
+		if len(e.buf) == 0 {
+			return true, "", io.EOF
+		}
+		e.deleteForward()
+	}
+	return false, "", nil
+}
+
+// Render returns the escape sequence that redraws the prompt on the given
+// terminal row (1-based) within a terminal of the given column width,
+// positioning the cursor at promptLen+pos. If a second consecutive Tab
+// produced a candidate list, it is drawn in columns above the prompt row
+// first, with the cursor saved and restored around it.
+func (e *Editor) Render(row, width int) string {
+	var b strings.Builder
+
+	if e.bell {
+		b.WriteString("\a")
+		e.bell = false
+	}
+
+	if len(e.candidates) > 0 {
+		rows := formatCandidateColumns(e.candidates, width)
+		b.WriteString(ansiCursorPositionSave)
+		for i, candidateRow := range rows {
+			targetRow := row - len(rows) + i
+			if targetRow < 1 {
+				targetRow = 1
+			}
+			fmt.Fprintf(&b, ansiCursorPositionMove, targetRow, 1)
+			b.WriteString(ansiLineClear)
+			b.WriteString(candidateRow)
+		}
+		b.WriteString(ansiCursorPositionRestore)
+	}
+
+	fmt.Fprintf(&b, ansiCursorPositionMove, row, 1)
+	b.WriteString(e.prompt)
+	b.WriteString(string(e.buf))
+	b.WriteString(ansiLineClear)
+	fmt.Fprintf(&b, ansiCursorPositionMove, row, len(e.prompt)+e.pos+1)
+	return b.String()
+}
+
+func (e *Editor) setLine(line string, pos int) {
+	e.buf = []rune(line)
+	e.pos = pos
+}
+
+func (e *Editor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+func (e *Editor) backspace() {
+	if e.pos == 0 {
+		return
+	}
+	e.buf = append(e.buf[:e.pos-1], e.buf[e.pos:]...)
+	e.pos--
+}
+
+func (e *Editor) deleteForward() {
+	if e.pos >= len(e.buf) {
+		return
+	}
+	e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+}
+
+func (e *Editor) killWordBackward() {
+	start := e.pos
+	for start > 0 && e.buf[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && e.buf[start-1] != ' ' {
+		start--
+	}
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+func (e *Editor) historyUp() {
+	if e.history == nil || e.history.Len() == 0 {
+		return
+	}
+	if e.historyIndex == -1 {
+		e.stash = append([]rune(nil), e.buf...)
+		e.historyIndex = 0
+	} else if e.historyIndex < e.history.Len()-1 {
+		e.historyIndex++
+	} else {
+		return
+	}
+	e.buf = []rune(e.history.At(e.historyIndex))
+	e.pos = len(e.buf)
+}
+
+func (e *Editor) historyDown() {
+	if e.historyIndex == -1 {
+		return
+	}
+	if e.historyIndex == 0 {
+		e.historyIndex = -1
+		e.buf = e.stash
+		e.stash = nil
+	} else {
+		e.historyIndex--
+		e.buf = []rune(e.history.At(e.historyIndex))
+	}
+	e.pos = len(e.buf)
+}
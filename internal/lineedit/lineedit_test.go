@@ -0,0 +1,102 @@
+package lineedit
+
+import (
+	"io"
+	"testing"
+)
+
+func feedRunes(e *Editor, s string) {
+	for _, r := range s {
+		e.Feed(Event{Key: KeyRune, Rune: r})
+	}
+}
+
+func TestFeedInsertAndBackspace(t *testing.T) {
+	e := New(nil)
+	e.Begin(":")
+	feedRunes(e, "abc")
+
+	e.Feed(Event{Key: KeyBackspace})
+	line, pos := e.Line()
+	if line != "ab" || pos != 2 {
+		t.Fatalf("Line() = %q, %d, want %q, %d", line, pos, "ab", 2)
+	}
+}
+
+func TestFeedEnterReturnsLineAndRecordsHistory(t *testing.T) {
+	h := NewHistory(10)
+	e := New(h)
+	e.Begin(":")
+	feedRunes(e, "w")
+
+	done, line, err := e.Feed(Event{Key: KeyEnter})
+	if !done || line != "w" || err != nil {
+		t.Fatalf("Feed(Enter) = %v, %q, %v, want true, %q, nil", done, line, err, "w")
+	}
+	if h.Len() != 1 || h.At(0) != "w" {
+		t.Fatalf("history = %v, want [w]", h)
+	}
+}
+
+func TestFeedEscCancels(t *testing.T) {
+	e := New(nil)
+	e.Begin(":")
+	feedRunes(e, "q")
+
+	done, _, err := e.Feed(Event{Key: KeyEsc})
+	if !done || err != ErrCancelled {
+		t.Fatalf("Feed(Esc) = %v, %v, want true, ErrCancelled", done, err)
+	}
+}
+
+func TestFeedCtrlDOnEmptyLineReturnsEOF(t *testing.T) {
+	e := New(nil)
+	e.Begin(":")
+
+	done, _, err := e.Feed(Event{Key: KeyCtrlD})
+	if !done || err != io.EOF {
+		t.Fatalf("Feed(CtrlD) = %v, %v, want true, io.EOF", done, err)
+	}
+}
+
+func TestHistoryUpDownRestoresStash(t *testing.T) {
+	h := NewHistory(10)
+	h.Add("first")
+	h.Add("second")
+
+	e := New(h)
+	e.Begin(":")
+	feedRunes(e, "unsent")
+
+	e.Feed(Event{Key: KeyArrowUp})
+	if line, _ := e.Line(); line != "second" {
+		t.Fatalf("after ArrowUp: Line() = %q, want %q", line, "second")
+	}
+
+	e.Feed(Event{Key: KeyArrowUp})
+	if line, _ := e.Line(); line != "first" {
+		t.Fatalf("after second ArrowUp: Line() = %q, want %q", line, "first")
+	}
+
+	e.Feed(Event{Key: KeyArrowDown})
+	if line, _ := e.Line(); line != "second" {
+		t.Fatalf("after ArrowDown: Line() = %q, want %q", line, "second")
+	}
+
+	e.Feed(Event{Key: KeyArrowDown})
+	if line, _ := e.Line(); line != "unsent" {
+		t.Fatalf("after second ArrowDown: Line() = %q, want %q", line, "unsent")
+	}
+}
+
+func TestRenderAnchorsPromptToRow(t *testing.T) {
+	e := New(nil)
+	e.Begin(":")
+	feedRunes(e, "w")
+
+	out := e.Render(24, 80)
+	want := "\033[24;1H:w\033[K\033[24;3H"
+	if out != want {
+		t.Fatalf("Render() = %q, want %q", out, want)
+	}
+}
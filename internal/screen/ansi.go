@@ -0,0 +1,167 @@
+package screen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences used by the ansi backend.
+const (
+	ansiCursorHide           = "\033[?25l"
+	ansiCursorShow           = "\033[?25h"
+	ansiCursorPositionMove   = "\033[%d;%dH"
+	ansiCursorPositionToHome = "\033[H"
+	ansiScreenAltOn          = "\033[?1049h"
+	ansiScreenAltOff         = "\033[?1049l"
+	ansiScrollbackClear      = "\033[3J"
+	ansiStyleReset           = "\033[0m"
+	ansiStyleBold            = "\033[1m"
+)
+
+type cell struct {
+	r     rune
+	style Style
+}
+
+// ansiScreen is vimgo's original backend: a direct termios + ANSI escape
+// sequence writer with no cell diffing, doing a full redraw on every
+// Flush, same as the editor's output before the Screen interface existed.
+type ansiScreen struct {
+	cells         [][]cell
+	columns, rows int
+
+	cursorX, cursorY int
+	cursorHidden     bool
+
+	oldState *term.State
+}
+
+// New creates the ANSI/termios Screen backend.
+func New() Screen {
+	return &ansiScreen{}
+}
+
+func (s *ansiScreen) Init() error {
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	s.oldState = oldState
+
+	if err := terminalRawConfigure(int(os.Stdin.Fd())); err != nil {
+		return err
+	}
+	if err := terminalRawConfigure(int(os.Stdout.Fd())); err != nil {
+		return err
+	}
+
+	columns, rows, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || columns <= 0 || rows <= 0 {
+		columns, rows = 80, 25
+	}
+	s.resize(columns, rows)
+
+	os.Stdout.WriteString(ansiScreenAltOn)
+	return nil
+}
+
+func (s *ansiScreen) resize(columns, rows int) {
+	s.columns, s.rows = columns, rows
+	s.cells = make([][]cell, rows)
+	for y := range s.cells {
+		s.cells[y] = make([]cell, columns)
+		for x := range s.cells[y] {
+			s.cells[y][x] = cell{r: ' '}
+		}
+	}
+}
+
+func (s *ansiScreen) Size() (columns, rows int) {
+	if columns, rows, err := term.GetSize(int(os.Stdout.Fd())); err == nil && columns > 0 && rows > 0 {
+		if columns != s.columns || rows != s.rows {
+			s.resize(columns, rows)
+		}
+	}
+	return s.columns, s.rows
+}
+
+func (s *ansiScreen) SetCell(x, y int, r rune, style Style) {
+	if y < 0 || y >= len(s.cells) || x < 0 || x >= s.columns {
+		return
+	}
+	s.cells[y][x] = cell{r: r, style: style}
+}
+
+func (s *ansiScreen) ShowCursor(x, y int) {
+	s.cursorHidden = false
+	s.cursorX, s.cursorY = x, y
+}
+
+func (s *ansiScreen) HideCursor() {
+	s.cursorHidden = true
+}
+
+func (s *ansiScreen) Flush() error {
+	var buf bytes.Buffer
+
+	buf.WriteString(ansiCursorHide)
+	buf.WriteString(ansiScrollbackClear)
+	buf.WriteString(ansiCursorPositionToHome)
+
+	var lastStyle Style
+	styled := false
+	for y, row := range s.cells {
+		for _, c := range row {
+			if c.style != lastStyle {
+				writeStyle(&buf, c.style)
+				lastStyle = c.style
+				styled = true
+			}
+			buf.WriteRune(c.r)
+		}
+		if y < len(s.cells)-1 {
+			buf.WriteString("\r\n")
+		}
+	}
+	if styled {
+		buf.WriteString(ansiStyleReset)
+	}
+
+	if s.cursorHidden {
+		buf.WriteString(ansiCursorHide)
+	} else {
+		fmt.Fprintf(&buf, ansiCursorPositionMove, s.cursorY+1, s.cursorX+1)
+		buf.WriteString(ansiCursorShow)
+	}
+
+	_, err := os.Stdout.Write(buf.Bytes())
+	return err
+}
+
+// WriteRaw writes data straight to the terminal, bypassing the cell grid.
+// Used by command-mode's line editor, which renders its own prompt line.
+func (s *ansiScreen) WriteRaw(data string) error {
+	_, err := os.Stdout.WriteString(data)
+	return err
+}
+
+func (s *ansiScreen) Close() error {
+	os.Stdout.WriteString(ansiScreenAltOff)
+	return term.Restore(int(os.Stdin.Fd()), s.oldState)
+}
+
+func writeStyle(buf *bytes.Buffer, style Style) {
+	buf.WriteString(ansiStyleReset)
+	if style.Bold {
+		buf.WriteString(ansiStyleBold)
+	}
+	if style.Fg.Valid {
+		fmt.Fprintf(buf, "\033[38;2;%d;%d;%dm", style.Fg.R, style.Fg.G, style.Fg.B)
+	}
+	if style.Bg.Valid {
+		fmt.Fprintf(buf, "\033[48;2;%d;%d;%dm", style.Bg.R, style.Bg.G, style.Bg.B)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Package screen abstracts vimgo's rendering backend behind a Screen
+interface, so editor code draws cells instead of writing ANSI escapes
+directly. Two implementations are provided: New (the original
+ANSI/termios backend) and NewTcell (built on github.com/gdamore/tcell/v2).
+*/
+package screen
+
+// Color is an RGB color. The zero value means "use the terminal's
+// default color" rather than black, so leaving a Style's Fg/Bg unset
+// keeps the terminal's normal colors.
+type Color struct {
+	R, G, B uint8
+	Valid   bool
+}
+
+// Style describes how a single cell should be rendered.
+type Style struct {
+	Fg   Color
+	Bg   Color
+	Bold bool
+}
+
+// Screen is the rendering backend the editor draws through. Callers set
+// cells for an entire frame, then call Flush to present them.
+type Screen interface {
+	// Init prepares the terminal for full-screen rendering.
+	Init() error
+	// Size returns the current terminal size in columns and rows.
+	Size() (columns, rows int)
+	// SetCell sets the rune and style to draw at (x, y) on the next Flush.
+	SetCell(x, y int, r rune, style Style)
+	// ShowCursor places the terminal cursor at (x, y).
+	ShowCursor(x, y int)
+	// HideCursor hides the terminal cursor.
+	HideCursor()
+	// Flush presents pending cell and cursor changes to the terminal.
+	Flush() error
+	// Close restores the terminal to its state before Init.
+	Close() error
+}
+
+// RawWriter is implemented by backends that can also accept pre-rendered
+// ANSI output written alongside SetCell-drawn cells. The ansi backend
+// supports this since it is a direct passthrough; the tcell backend does
+// not, since tcell owns and diffs its own back buffer.
+type RawWriter interface {
+	WriteRaw(data string) error
+}
+
+// KeyCode mirrors vimgo's own key taxonomy so that backends which must
+// drive their own input loop (tcell owns the terminal once Init'd) can
+// feed events through the same shape the main loop already understands.
+type KeyCode int
+
+const (
+	KeyUnknown KeyCode = iota
+	KeyArrowUp
+	KeyArrowDown
+	KeyArrowLeft
+	KeyArrowRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyDelete
+	KeyBackspace
+	KeyTab
+	KeyEnter
+	KeyEsc
+	KeyRune
+	KeyResize // emitted instead of a key when the terminal was resized
+)
+
+// KeyEvent is a single input event produced by an InputScreen.
+type KeyEvent struct {
+	KeyCode KeyCode
+	Rune    rune
+	Ctrl    bool
+}
+
+// InputScreen is implemented by backends that must supply their own input
+// events rather than letting the caller read raw bytes off stdin itself.
+type InputScreen interface {
+	Screen
+	PollKey() (KeyEvent, error)
+}
@@ -0,0 +1,124 @@
+package screen
+
+import (
+	"errors"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// tcellScreen adapts github.com/gdamore/tcell/v2 to the Screen interface.
+// Unlike the ansi backend it lets tcell diff cells internally, and it owns
+// input itself (via PollKey) since tcell needs sole control of the tty.
+type tcellScreen struct {
+	screen tcell.Screen
+}
+
+// NewTcell creates the tcell-backed Screen backend.
+func NewTcell() Screen {
+	return &tcellScreen{}
+}
+
+func (s *tcellScreen) Init() error {
+	scr, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	if err := scr.Init(); err != nil {
+		return err
+	}
+	scr.HideCursor()
+	s.screen = scr
+	return nil
+}
+
+func (s *tcellScreen) Size() (columns, rows int) {
+	return s.screen.Size()
+}
+
+func (s *tcellScreen) SetCell(x, y int, r rune, style Style) {
+	s.screen.SetContent(x, y, r, nil, toTcellStyle(style))
+}
+
+func (s *tcellScreen) ShowCursor(x, y int) {
+	s.screen.ShowCursor(x, y)
+}
+
+func (s *tcellScreen) HideCursor() {
+	s.screen.HideCursor()
+}
+
+func (s *tcellScreen) Flush() error {
+	s.screen.Show()
+	return nil
+}
+
+func (s *tcellScreen) Close() error {
+	s.screen.Fini()
+	return nil
+}
+
+// PollKey blocks for the next tcell event and translates it into vimgo's
+// own KeyEvent shape. Resizes are reported as KeyResize so the main loop
+// can refresh its cached terminal size the same way a SIGWINCH would.
+func (s *tcellScreen) PollKey() (KeyEvent, error) {
+	switch ev := s.screen.PollEvent().(type) {
+	case *tcell.EventResize:
+		return KeyEvent{KeyCode: KeyResize}, nil
+	case *tcell.EventKey:
+		return tcellKeyEventToKeyEvent(ev), nil
+	default:
+		return KeyEvent{}, errors.New("screen: tcell event loop stopped")
+	}
+}
+
+func tcellKeyEventToKeyEvent(ev *tcell.EventKey) KeyEvent {
+	switch ev.Key() {
+	case tcell.KeyUp:
+		return KeyEvent{KeyCode: KeyArrowUp}
+	case tcell.KeyDown:
+		return KeyEvent{KeyCode: KeyArrowDown}
+	case tcell.KeyLeft:
+		return KeyEvent{KeyCode: KeyArrowLeft}
+	case tcell.KeyRight:
+		return KeyEvent{KeyCode: KeyArrowRight}
+	case tcell.KeyHome:
+		return KeyEvent{KeyCode: KeyHome}
+	case tcell.KeyEnd:
+		return KeyEvent{KeyCode: KeyEnd}
+	case tcell.KeyPgUp:
+		return KeyEvent{KeyCode: KeyPageUp}
+	case tcell.KeyPgDn:
+		return KeyEvent{KeyCode: KeyPageDown}
+	case tcell.KeyDelete:
+		return KeyEvent{KeyCode: KeyDelete}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		return KeyEvent{KeyCode: KeyBackspace}
+	case tcell.KeyTab:
+		return KeyEvent{KeyCode: KeyTab}
+	case tcell.KeyEnter:
+		return KeyEvent{KeyCode: KeyEnter, Rune: '\r'}
+	case tcell.KeyEsc:
+		return KeyEvent{KeyCode: KeyEsc}
+	case tcell.KeyRune:
+		return KeyEvent{KeyCode: KeyRune, Rune: ev.Rune()}
+	default:
+		if ev.Key() >= tcell.KeyCtrlA && ev.Key() <= tcell.KeyCtrlZ {
+			return KeyEvent{KeyCode: KeyRune, Rune: rune('a' + int(ev.Key()-tcell.KeyCtrlA)), Ctrl: true}
+		}
+		return KeyEvent{KeyCode: KeyUnknown}
+	}
+}
+
+func toTcellStyle(style Style) tcell.Style {
+	s := tcell.StyleDefault
+	if style.Bold {
+		s = s.Bold(true)
+	}
+	if style.Fg.Valid {
+		s = s.Foreground(tcell.NewRGBColor(int32(style.Fg.R), int32(style.Fg.G), int32(style.Fg.B)))
+	}
+	if style.Bg.Valid {
+		s = s.Background(tcell.NewRGBColor(int32(style.Bg.R), int32(style.Bg.G), int32(style.Bg.B)))
+	}
+	return s
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package screen
+
+// terminalRawConfigure is a no-op on Windows: golang.org/x/term.MakeRaw
+// already puts the console into the raw mode needed for reading keys, and
+// the termios flags terminalRawConfigure clears on Unix have no Windows
+// equivalent.
+func terminalRawConfigure(fd int) error {
+	return nil
+}
@@ -6,34 +6,20 @@ package main
 
 import (
 	"bufio"
-	"bytes"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"strings"
-	"time"
 
-	"golang.org/x/term"
+	"github.com/schillermann/vimgo-playground/internal/buffer"
+	"github.com/schillermann/vimgo-playground/internal/lineedit"
+	"github.com/schillermann/vimgo-playground/internal/screen"
 )
 
 const editorVersion = "0.1"
 
-// ANSI escape sequences
-const (
-	ansiCursorHide                    = "\033[?25l"
-	ansiCursorPositionMove            = "\033[%d;%dH"
-	ansiCursorPositionMoveToOffScreen = "\033[999;999H"
-	ansiCursorPositionRequest         = "\033[6n"
-	ansiCursorPositionRestore         = "\0338"
-	ansiCursorPositionSave            = "\0337"
-	ansiCursorPositionToHome          = "\033[H"
-	ansiCursorShow                    = "\033[?25h"
-	ansiLineClear                     = "\033[K"
-	ansiScreenAltOff                  = "\033[?1049l"
-	ansiScreenAltOn                   = "\033[?1049h"
-	ansiScreenClear                   = "\033[2J"
-	ansiScrollbackClear               = "\033[3J"
-)
+var backendFlag = flag.String("backend", "ansi", `rendering backend: "ansi" or "tcell"`)
 
 // KeyCode represents special non-printable keys.
 type KeyCode int
@@ -49,6 +35,8 @@ const (
 	KeyPageUp
 	KeyPageDown
 	KeyDelete
+	KeyBackspace
+	KeyTab
 	KeyEnter
 	KeyCtrl // meta for ctrl combos, printable rune will be passed too
 	KeyEsc
@@ -63,7 +51,12 @@ type KeyEvent struct {
 }
 
 var cursorIndexX, cursorIndexY int
-var editorLines []string // current in-memory buffer lines
+var currentBuffer = buffer.New(nil) // current in-memory document
+
+// terminalCols and terminalRows cache the last known terminal size. They
+// are only recomputed when a resize event arrives, instead of on every
+// loop iteration.
+var terminalCols, terminalRows int
 
 // readKeyBlocking reads from stdin (one or more bytes) and returns a KeyEvent.
 // It assumes stdin is in raw mode.
@@ -75,6 +68,13 @@ func readKeyBlocking(inputReader *bufio.Reader) (KeyEvent, error) {
 		return ev, err
 	}
 
+	// Tab (9) is technically Ctrl-I, but terminals only ever send it for
+	// the Tab key, so treat it as its own KeyCode rather than a ctrl combo.
+	if inputByte == 9 {
+		ev.KeyCode = KeyTab
+		return ev, nil
+	}
+
 	// handle Ctrl-keys: ASCII 1..26 => Ctrl-A..Ctrl-Z
 	if inputByte >= 1 && inputByte <= 26 {
 		ev.KeyCode = KeyRune
@@ -89,6 +89,12 @@ func readKeyBlocking(inputReader *bufio.Reader) (KeyEvent, error) {
 		return ev, nil
 	}
 
+	// DEL (127), the byte most terminals send for the Backspace key.
+	if inputByte == 127 {
+		ev.KeyCode = KeyBackspace
+		return ev, nil
+	}
+
 	// printable ordinary characters (including space, digits, letters)
 	if inputByte >= 32 && inputByte <= 126 {
 		if inputByte == 13 || inputByte == '\n' {
@@ -203,110 +209,85 @@ func readKeyBlocking(inputReader *bufio.Reader) (KeyEvent, error) {
 	return ev, nil
 }
 
-func getTerminalSize(fd int) (columns, rows int, err error) {
-	columns, rows, err = term.GetSize(fd)
-	if err == nil && columns > 0 && rows > 0 {
-		return columns, rows, nil
+// setRowText clears row y to spaces and then overlays text, truncated to
+// columns, matching the left-aligned, clipped rendering drawRows used to
+// do for each line with ansiLineClear.
+func setRowText(scr screen.Screen, y int, text string, columns int) {
+	for x := 0; x < columns; x++ {
+		scr.SetCell(x, y, ' ', screen.Style{})
 	}
-
-	// Fallback: use cursor position query (CSI 6n)
-	fmt.Print(ansiCursorPositionSave)
-	fmt.Print(ansiCursorPositionMoveToOffScreen)
-	fmt.Print(ansiCursorPositionRequest)
-
-	// Read the response: ESC [ rows ; cols R
-	responceBuffer := make([]byte, 32)
-	os.Stdin.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
-	responseSize, _ := os.Stdin.Read(responceBuffer)
-	os.Stdin.SetReadDeadline(time.Time{}) // clear deadline
-
-	// Restore cursor
-	fmt.Print(ansiCursorPositionRestore)
-
-	// Parse response if valid
-	if responseSize > 0 {
-		// Expected: ESC [ rows ; cols R
-		esc := bytes.IndexByte(responceBuffer[:responseSize], '[')
-		rowsAndCols := bytes.IndexByte(responceBuffer[:responseSize], 'R')
-		if esc >= 0 && rowsAndCols > esc {
-			var rows, cols int
-			if _, perr := fmt.Sscanf(string(responceBuffer[esc+1:rowsAndCols]), "%d;%d", &rows, &cols); perr == nil {
-				if cols > 0 && rows > 0 {
-					return cols, rows, nil
-				}
-			}
-		}
+	runes := []rune(text)
+	if len(runes) > columns {
+		runes = runes[:columns]
+	}
+	for x, r := range runes {
+		scr.SetCell(x, y, r, screen.Style{})
 	}
-
-	const defaultRows = 25
-	const defaultCols = 80
-
-	// Fallback to safe default
-	return defaultRows, defaultCols, fmt.Errorf("could not determine terminal size, using defaults %dx%d", defaultRows, defaultCols)
 }
 
-func drawRows(buf *bytes.Buffer, terminalColumns, terminalRows int) {
-	for i := 0; i < terminalRows; i++ {
-		buf.WriteString(ansiLineClear)
-
-		if len(editorLines) == 0 {
-			welcomeRow := terminalRows / 3
-
-			if i == welcomeRow {
+func drawBuffer(scr screen.Screen) {
+	lineCount := currentBuffer.LineCount()
+	for y := 0; y < terminalRows; y++ {
+		if lineCount == 0 {
+			if y == terminalRows/3 {
 				welcome := fmt.Sprintf("VimGo -- version %s", editorVersion)
-				buf.WriteString("~")
-
 				welcomeText := welcome
-				if len(welcomeText) > terminalColumns {
-					welcomeText = welcomeText[:terminalColumns]
+				if len(welcomeText) > terminalCols {
+					welcomeText = welcomeText[:terminalCols]
 				}
-				padding := (terminalColumns - len(welcome)) / 2
+				padding := (terminalCols - len(welcome)) / 2
+				line := "~"
 				if padding > 0 {
-					buf.WriteString(strings.Repeat(" ", padding))
+					line += strings.Repeat(" ", padding)
 				}
-				buf.WriteString(welcomeText)
+				setRowText(scr, y, line+welcomeText, terminalCols)
 			} else {
-				buf.WriteString("~")
-			}
-		} else {
-			if i < len(editorLines) {
-				line := editorLines[i]
-				if len(line) > terminalColumns {
-					line = line[:terminalColumns]
-				}
-				buf.WriteString(line)
-			} else {
-				buf.WriteString("~")
+				setRowText(scr, y, "~", terminalCols)
 			}
+			continue
 		}
 
-		if i < terminalRows-1 {
-			buf.WriteString("\r\n")
+		if y < lineCount {
+			setRowText(scr, y, string(currentBuffer.Line(y)), terminalCols)
+		} else {
+			setRowText(scr, y, "~", terminalCols)
 		}
 	}
 }
 
-func refreshTerminal(columns, rows int) error {
-	var buf bytes.Buffer
-
-	buf.WriteString(ansiCursorHide)
-
-	// Fullscreen - Accumulate screen update in buffer
-	buf.WriteString(ansiScrollbackClear)
-	buf.WriteString(ansiCursorPositionToHome)
-
-	drawRows(&buf, columns, rows)
+// drawCommandLine overlays the command-mode prompt onto the bottom row by
+// setting cells directly. It is the fallback used when the backend can't
+// also accept raw ANSI output (see render).
+func drawCommandLine(scr screen.Screen) {
+	line, pos := commandEditor.Line()
+	prompt := commandEditor.Prompt()
+	setRowText(scr, terminalRows-1, prompt+line, terminalCols)
+	scr.ShowCursor(len([]rune(prompt))+pos, terminalRows-1)
+}
 
-	buf.WriteString(fmt.Sprintf(ansiCursorPositionMove, cursorIndexY+1, cursorIndexX+1))
-	buf.WriteString(ansiCursorShow)
+func render(scr screen.Screen) error {
+	drawBuffer(scr)
 
-	// Single write
-	_, writeErr := os.Stdout.Write(buf.Bytes())
+	if editorMode != ModeCommand {
+		scr.ShowCursor(cursorIndexX, cursorIndexY)
+		return scr.Flush()
+	}
 
-	return writeErr
+	// Command mode: on the ansi backend, keep rendering the prompt through
+	// lineedit's own Render (it owns the tab-completion candidate display);
+	// tcell must have the prompt set as cells before its single Flush/Show.
+	rawWriter, ok := scr.(screen.RawWriter)
+	if !ok {
+		drawCommandLine(scr)
+		return scr.Flush()
+	}
+	if err := scr.Flush(); err != nil {
+		return err
+	}
+	return rawWriter.WriteRaw(commandEditor.Render(terminalRows, terminalCols))
 }
 
-func editorMoveCursor(ev KeyEvent, terminalColumns, terminalRows int) {
+func editorMoveCursor(ev KeyEvent) {
 	// Vim-style movement: h, j, k, l
 	switch ev.Rune {
 	case 'h':
@@ -314,17 +295,13 @@ func editorMoveCursor(ev KeyEvent, terminalColumns, terminalRows int) {
 			cursorIndexX--
 		}
 	case 'l':
-		if cursorIndexX < terminalColumns-1 {
-			cursorIndexX++
-		}
+		cursorIndexX++
 	case 'k':
 		if cursorIndexY > 0 {
 			cursorIndexY--
 		}
 	case 'j':
-		if cursorIndexY < terminalRows-1 {
-			cursorIndexY++
-		}
+		cursorIndexY++
 	}
 
 	// Page Up/Down and Home/End navigation.
@@ -332,94 +309,148 @@ func editorMoveCursor(ev KeyEvent, terminalColumns, terminalRows int) {
 	case KeyPageUp:
 		cursorIndexY = 0
 	case KeyPageDown:
-		if terminalRows > 0 {
-			cursorIndexY = terminalRows - 1
-		}
+		cursorIndexY = currentBuffer.LineCount() - 1
 	case KeyHome:
 		cursorIndexX = 0
 	case KeyEnd:
-		if terminalColumns > 0 {
-			cursorIndexX = terminalColumns - 1
-		}
+		cursorIndexX = len([]rune(string(currentBuffer.Line(cursorIndexY))))
 	}
+
+	clampCursor()
 }
 
-func editorOpen(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		editorLines = []string{}
-		return err
+// clampCursor keeps cursorIndexX/Y within the buffer's actual content,
+// rather than the terminal grid: Y is bounded by the number of lines and
+// X by the current line's length, so movement and Insert mode always
+// agree on where the cursor really is.
+func clampCursor() {
+	lineCount := currentBuffer.LineCount()
+	if lineCount == 0 {
+		cursorIndexX, cursorIndexY = 0, 0
+		return
+	}
+	if cursorIndexY >= lineCount {
+		cursorIndexY = lineCount - 1
+	}
+	if cursorIndexY < 0 {
+		cursorIndexY = 0
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		editorLines = append(editorLines, scanner.Text())
+	lineLen := len([]rune(string(currentBuffer.Line(cursorIndexY))))
+	if cursorIndexX > lineLen {
+		cursorIndexX = lineLen
 	}
+	if cursorIndexX < 0 {
+		cursorIndexX = 0
+	}
+}
 
-	if err := scanner.Err(); err != nil {
-		return err
+// cursorOffset converts the cursor's line/column position into a byte
+// offset into currentBuffer, the form Buffer.Insert/Delete operate on.
+func cursorOffset() int {
+	offset := currentBuffer.LineStart(cursorIndexY)
+	line := []rune(string(currentBuffer.Line(cursorIndexY)))
+	x := cursorIndexX
+	if x > len(line) {
+		x = len(line)
 	}
+	return offset + len(string(line[:x]))
+}
 
+func editorOpen(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		currentBuffer = buffer.New(nil)
+		return err
+	}
+	currentBuffer = buffer.New(data)
 	return nil
 }
 
-func main() {
-	// put stdin into raw mode
-	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
-	if err != nil {
-		log.Fatalf("Fatal error during setting raw mode: %v", err)
+// newScreen constructs the Screen backend selected by --backend.
+func newScreen() screen.Screen {
+	if *backendFlag == "tcell" {
+		return screen.NewTcell()
 	}
+	return screen.New()
+}
 
-	stdin := int(os.Stdin.Fd())
-	stdout := int(os.Stdout.Fd())
+func main() {
+	flag.Parse()
 
-	if err := terminalRawConfigure(stdin); err != nil {
-		panic(err)
+	scr := newScreen()
+	if err := scr.Init(); err != nil {
+		log.Fatalf("Fatal error during terminal init: %v", err)
 	}
-	if err := terminalRawConfigure(stdout); err != nil {
-		panic(err)
-	}
-
-	// enter new screen buffer
-	fmt.Print(ansiScreenAltOn)
-	// leave new screen buffer
-	defer fmt.Print(ansiScreenAltOff)
+	defer scr.Close()
 
-	// restores the terminal settings after program exit or abort
-	defer term.Restore(int(os.Stdin.Fd()), oldState)
+	terminalCols, terminalRows = scr.Size()
 
-	reader := bufio.NewReader(os.Stdin)
 	keyChannel := make(chan KeyEvent, 1)
-
-	// Start a single goroutine that continuously reads key events.
-	go func() {
-		for {
-			keyEvent, err := readKeyBlocking(reader)
-			if err != nil {
-				close(keyChannel)
-				return
+	var resizeChannel <-chan struct{}
+
+	if inputScreen, ok := scr.(screen.InputScreen); ok {
+		// This backend owns input itself (tcell needs sole control of the tty).
+		ownResizeChannel := make(chan struct{}, 1)
+		resizeChannel = ownResizeChannel
+		go func() {
+			for {
+				ev, err := inputScreen.PollKey()
+				if err != nil {
+					close(keyChannel)
+					return
+				}
+				if ev.KeyCode == screen.KeyResize {
+					select {
+					case ownResizeChannel <- struct{}{}:
+					default:
+					}
+					continue
+				}
+				select {
+				case keyChannel <- screenKeyEventToKeyEvent(ev):
+				default:
+					// drop this key press if main loop hasn't consumed the previous event
+				}
 			}
-			select {
-			case keyChannel <- keyEvent:
-			default:
-				// drop this key press if main loop hasn't consumed the previous event
+		}()
+	} else {
+		reader := bufio.NewReader(os.Stdin)
+		go func() {
+			for {
+				keyEvent, err := readKeyBlocking(reader)
+				if err != nil {
+					close(keyChannel)
+					return
+				}
+				select {
+				case keyChannel <- keyEvent:
+				default:
+					// drop this key press if main loop hasn't consumed the previous event
+				}
 			}
-		}
-	}()
+		}()
+		resizeChannel = startResizeWatcher(scr)
+	}
 
 	if len(os.Args) > 1 {
-		if err := openEditor(os.Args[1]); err != nil {
+		if err := editorOpen(os.Args[1]); err != nil {
 			log.Fatalf("Fatal error during opening the file %s: %v", os.Args[1], err)
 		}
+		currentFilename = os.Args[1]
+	}
+
+	var historyErr error
+	commandHistory, historyErr = lineedit.LoadHistory(historyFilePath(), historyMaxEntries)
+	if historyErr != nil {
+		commandHistory = lineedit.NewHistory(historyMaxEntries)
 	}
+	defer commandHistory.Save(historyFilePath())
+	commandEditor = lineedit.New(commandHistory)
+	commandEditor.SetCompleter(exCompleter{})
 
 	for {
-		terminalColumns, terminalRows, err := getTerminalSize(int(os.Stdout.Fd()))
-		if err != nil {
-			log.Fatalf("Fatal error during reading the number of terminal columns and rows: %w", err)
-		}
-		if err := refreshTerminal(terminalColumns, terminalRows); err != nil {
+		if err := render(scr); err != nil {
 			log.Fatalf("Fatal error during refreshing screen: %v", err)
 		}
 
@@ -429,13 +460,31 @@ func main() {
 				return
 			}
 
-			editorMoveCursor(ev, terminalColumns, terminalRows)
-
-			// Quit on Ctrl-Q
-			if ev.Ctrl && ev.Rune == 'q' {
-				fmt.Println("\nQuit (Ctrl-Q). Restoring terminal and exiting.")
-				return
+			switch editorMode {
+			case ModeCommand:
+				done, line, feedErr := commandEditor.Feed(keyEventToLineEditEvent(ev))
+				if done {
+					editorMode = ModeNormal
+					if feedErr == nil && executeExCommand(line) {
+						return
+					}
+				}
+			case ModeInsert:
+				handleInsertKey(ev)
+			case ModeVisual:
+				handleVisualKey(ev)
+			default: // ModeNormal
+				if ev.Ctrl && ev.Rune == 'q' {
+					fmt.Println("\nQuit (Ctrl-Q). Restoring terminal and exiting.")
+					return
+				}
+				if handleNormalKey(ev) {
+					return
+				}
 			}
+
+		case <-resizeChannel:
+			terminalCols, terminalRows = scr.Size()
 		}
 	}
 }
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/schillermann/vimgo-playground/internal/screen"
+)
+
+// startResizeWatcher installs a SIGWINCH handler and returns a channel that
+// receives a value each time the terminal is resized. The channel is
+// buffered by one slot so a burst of SIGWINCHes collapses into a single
+// pending resize, matching how keyChannel drops keypresses under backpressure.
+// scr is unused here (SIGWINCH carries no size of its own) but kept in the
+// signature so it matches resize_windows.go's polling variant.
+func startResizeWatcher(scr screen.Screen) <-chan struct{} {
+	resizeChannel := make(chan struct{}, 1)
+
+	sigChannel := make(chan os.Signal, 1)
+	signal.Notify(sigChannel, syscall.SIGWINCH)
+
+	go func() {
+		for range sigChannel {
+			select {
+			case resizeChannel <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return resizeChannel
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package main
+
+import (
+	"time"
+
+	"github.com/schillermann/vimgo-playground/internal/screen"
+)
+
+// startResizeWatcher has no SIGWINCH equivalent on Windows, so it polls
+// scr.Size() on a 250ms ticker and only signals when the size actually
+// changed, keeping the event-driven contract the main loop relies on.
+func startResizeWatcher(scr screen.Screen) <-chan struct{} {
+	resizeChannel := make(chan struct{}, 1)
+
+	go func() {
+		lastCols, lastRows := terminalCols, terminalRows
+
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			cols, rows := scr.Size()
+			if cols == lastCols && rows == lastRows {
+				continue
+			}
+			lastCols, lastRows = cols, rows
+
+			select {
+			case resizeChannel <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return resizeChannel
+}